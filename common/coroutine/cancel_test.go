@@ -0,0 +1,83 @@
+package coroutine
+
+import "testing"
+
+func TestWithCancelPropagatesToChildren(t *testing.T) {
+	var childCanceled bool
+
+	d := NewDispatcher(func(ctx Context) {
+		parent, cancel := WithCancel(ctx)
+		child, _ := WithCancel(parent)
+		NewCoroutine(parent, func(ctx Context) {
+			child.Done().Recv(ctx)
+			childCanceled = true
+		})
+		cancel()
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if !childCanceled {
+		t.Fatal("canceling the parent context should unblock Recv on the child's Done channel")
+	}
+}
+
+func TestDispatcherCloseCancelsRootContext(t *testing.T) {
+	var canceled bool
+
+	d := NewDispatcher(func(ctx Context) {
+		NewCoroutine(ctx, func(ctx Context) {
+			_, more := ctx.Done().Recv(ctx)
+			canceled = !more
+		})
+	})
+	if err := d.ExecuteUntilAllBlocked(); err != nil {
+		t.Fatalf("coroutine panicked: %v\n%v", err, err.StackTrace())
+	}
+	if d.IsDone() {
+		t.Fatal("dispatcher should not be done while a coroutine is still blocked on ctx.Done()")
+	}
+
+	d.Close()
+	if err := d.ExecuteUntilAllBlocked(); err != nil {
+		t.Fatalf("coroutine panicked after Close(): %v\n%v", err, err.StackTrace())
+	}
+
+	if !canceled {
+		t.Fatal("Dispatcher.Close() should cancel the root context, unblocking coroutines waiting on ctx.Done()")
+	}
+}
+
+func TestWithTimeoutCancelsAfterDuration(t *testing.T) {
+	var timedOut bool
+
+	d := NewDispatcher(func(ctx Context) {
+		timeoutCtx, cancel := WithTimeout(ctx, 0)
+		defer cancel()
+		_, more := timeoutCtx.Done().Recv(ctx)
+		timedOut = !more
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if !timedOut {
+		t.Fatal("WithTimeout's Done channel should close once the timeout elapses")
+	}
+}
+
+func TestWithDeadlineCancelsOnceReached(t *testing.T) {
+	var deadlineReached bool
+
+	d := NewDispatcher(func(ctx Context) {
+		deadlineCtx, cancel := WithDeadline(ctx, dispatcherNow(ctx))
+		defer cancel()
+		_, more := deadlineCtx.Done().Recv(ctx)
+		deadlineReached = !more
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if !deadlineReached {
+		t.Fatal("WithDeadline's Done channel should close once the deadline is reached")
+	}
+}
@@ -1,20 +1,41 @@
 package coroutine
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
 
-// Channel must be used instead of native go channel by coroutine code.
-// Use Context.NewChannel method to create an instance.
-type Channel interface {
-	Recv(ctx Context) (v interface{}, more bool)    // more is false when channel is closed
-	RecvAsync() (v interface{}, ok bool, more bool) // ok is true when value was returned, more is false when channel is closed
+// ReceiveChannel is the receive half of a Channel. Use it for values (e.g. a
+// Done() channel) that callers are only allowed to observe, never send to.
+type ReceiveChannel[T any] interface {
+	Recv(ctx Context) (v T, more bool)    // more is false when channel is closed
+	RecvAsync() (v T, ok bool, more bool) // ok is true when value was returned, more is false when channel is closed
+}
 
-	Send(ctx Context, v interface{})
-	SendAsync(v interface{}) (ok bool) // ok when value was sent
-	Close()                            // prohibit sends
+// SendChannel is the send half of a Channel. Use it for values that callers
+// are only allowed to produce, never consume.
+type SendChannel[T any] interface {
+	Send(ctx Context, v T)
+	SendAsync(v T) (ok bool) // ok when value was sent
+	Close()                  // prohibit sends
 }
 
+// Channel must be used instead of native go channel by coroutine code.
+// Use NewChannel or one of its variants to create an instance.
+type Channel[T any] interface {
+	ReceiveChannel[T]
+	SendChannel[T]
+}
+
+// AnyChannel is a Channel carrying untyped values. It exists for call sites
+// that build up channels dynamically and cannot name T at compile time;
+// prefer Channel[T] everywhere else.
+type AnyChannel = Channel[interface{}]
+
 // RecvCaseFunc is executed when a value is received from the corresponding channel
-type RecvCaseFunc func(v interface{}, more bool)
+type RecvCaseFunc[T any] func(v T, more bool)
 
 // SendCaseFunc is executed when value was sent to a correspondent channel
 type SendCaseFunc func()
@@ -22,13 +43,425 @@ type SendCaseFunc func()
 // DefaultCaseFunc is executed when none of the channel cases executed
 type DefaultCaseFunc func()
 
+// SelectResult reports which case fired during a call to Selector.Select, so
+// generic helpers can be written over a selector assembled programmatically
+// from a list of cases that may or may not include a default.
+type SelectResult struct {
+	// Index is the zero-based position, in registration order, of the
+	// Recv/Send/Future/Acquire case that fired. It is -1 when Default fired.
+	Index int
+	// Default is true when no case was ready and the default case fired.
+	Default bool
+}
+
 // Selector must be used instead of native go select by coroutine code
 // Use Context.NewSelector method to create an instance.
 type Selector interface {
-	AddRecv(c Channel, f RecvCaseFunc) Selector
-	AddSend(c Channel, v interface{}, f SendCaseFunc) Selector
-	AddDefault(f DefaultCaseFunc)
-	Select(ctx Context)
+	// AddDefault registers f as the case that runs when Select is called
+	// and no other case is ready. Registering a second default panics —
+	// exactly like a second `default:` in a native select statement is a
+	// compile error.
+	AddDefault(f DefaultCaseFunc) Selector
+	// HasDefault reports whether AddDefault has been called on this
+	// Selector, so code building a selector dynamically from a list of
+	// cases can tell whether it still needs to add one.
+	HasDefault() bool
+	// AddFuture adds a case that fires f once future is ready, i.e. once
+	// future.Get would not block. It lets a coroutine race several futures
+	// and a cancellation channel in a single deterministic select.
+	AddFuture(future Future, f func(Future)) Selector
+	// AddAcquire adds a case that fires f once a slot on sem can be
+	// acquired without blocking. It lets a coroutine race a semaphore slot
+	// against a cancellation channel in the same select.
+	AddAcquire(sem Semaphore, f func()) Selector
+	// Select blocks until one registered case is ready, runs it, and
+	// reports which one fired. Every Recv/Send/Future/Acquire case is
+	// checked for readiness first; the default, if any, only runs when
+	// none of them are.
+	Select(ctx Context) SelectResult
+
+	addRecv(c *channelImpl, f RecvCaseFunc[interface{}]) Selector
+	addSend(c *channelImpl, v interface{}, f SendCaseFunc) Selector
+}
+
+// rawChannel is implemented by every Channel[T] returned from this package
+// and exposes the untyped channelImpl backing it, so Selector (and other
+// internals that are necessarily generic-free, such as Future and Semaphore)
+// can operate on it without knowing T.
+type rawChannel interface {
+	raw() *channelImpl
+}
+
+// blockedDescriber is implemented by primitives, such as futureImpl and
+// semaphoreImpl, that are built on a channelImpl but want their own line in
+// Dispatcher.StackTrace() instead of the generic "blocked on channel <name>"
+// one a plain channel reports.
+type blockedDescriber interface {
+	blockedDescription() string
+}
+
+// AddRecv adds a receive case to s. f is called with the value in its static
+// type T when a value becomes available on c.
+func AddRecv[T any](s Selector, c ReceiveChannel[T], f RecvCaseFunc[T]) Selector {
+	return s.addRecv(c.(rawChannel).raw(), func(v interface{}, more bool) {
+		f(asT[T](v), more)
+	})
+}
+
+// AddSend adds a send case to s that offers v on c.
+func AddSend[T any](s Selector, c SendChannel[T], v T, f SendCaseFunc) Selector {
+	return s.addSend(c.(rawChannel).raw(), v, f)
+}
+
+// asT recovers a value of its static type from the interface{} storage
+// shared by every channelImpl, treating a nil value as the zero value of T.
+func asT[T any](v interface{}) T {
+	var typed T
+	if v != nil {
+		typed = v.(T)
+	}
+	return typed
+}
+
+// Future represents the result of an asynchronous operation, such as an
+// activity or child workflow invocation, that may not be ready yet. Use
+// NewFuture to create one together with the Settable that completes it.
+type Future interface {
+	// Get blocks the current coroutine until the future is ready, then
+	// returns its value and error.
+	Get(ctx Context) (interface{}, error)
+	// IsReady returns true if Get would return without blocking.
+	IsReady() bool
+}
+
+// Settable is the writable counterpart of a Future, normally held privately
+// by the code producing the future's result.
+type Settable interface {
+	// Set completes the Future with v and err. Subsequent calls are no-ops.
+	Set(v interface{}, err error)
+	// Chain completes the Future with the eventual outcome of other.
+	Chain(other Future)
+}
+
+// futureSettled is the value type carried over a future's internal channel.
+type futureSettled struct {
+	value interface{}
+	err   error
+}
+
+// futureImpl is the default Future/Settable implementation. It is built
+// entirely on top of a single buffered Channel so it participates in
+// deterministic Selector scheduling exactly like any other channel.
+type futureImpl struct {
+	ctx     Context
+	name    string
+	channel Channel[futureSettled]
+	settled futureSettled
+	ready   bool
+}
+
+// NewFuture creates a new Future and its Settable counterpart. Completing
+// the Settable unblocks every coroutine waiting on the Future via Get or
+// Selector.AddFuture.
+func NewFuture(ctx Context) (Future, Settable) {
+	ctxImpl := ctx.Value(contextKey).(*coroutineState)
+	ctxImpl.dispatcher.futureSequence++
+	name := fmt.Sprintf("future-%v", ctxImpl.dispatcher.futureSequence)
+	impl := &futureImpl{ctx: ctx, name: name, channel: NewNamedBufferedChannel[futureSettled](ctx, name, 1)}
+	return impl, impl
+}
+
+func (f *futureImpl) Get(ctx Context) (interface{}, error) {
+	if !f.ready {
+		f.settled, _ = f.channel.Recv(ctx)
+		f.ready = true
+	}
+	return f.settled.value, f.settled.err
+}
+
+// IsReady reports whether Get would return without blocking. f.ready alone
+// only reflects whether Get has already been called; probe the underlying
+// channel here too so a future settled via Set/Chain, but never yet Get,
+// reports ready as soon as it is. The probe is safe to repeat: once it
+// consumes the buffered value it caches it on f, so later calls see f.ready
+// and never touch the channel again.
+func (f *futureImpl) IsReady() bool {
+	if !f.ready {
+		if settled, ok, _ := f.channel.RecvAsync(); ok {
+			f.settled = settled
+			f.ready = true
+		}
+	}
+	return f.ready
+}
+
+func (f *futureImpl) Set(v interface{}, err error) {
+	f.channel.SendAsync(futureSettled{value: v, err: err})
+}
+
+// Chain spawns an internal coroutine that waits for other and forwards its
+// outcome to f, the same deterministic-propagation idiom used elsewhere in
+// this package instead of a callback list.
+func (f *futureImpl) Chain(other Future) {
+	NewCoroutine(f.ctx, func(ctx Context) {
+		v, err := other.Get(ctx)
+		f.Set(v, err)
+	})
+}
+
+func (f *futureImpl) raw() *channelImpl {
+	return f.channel.(rawChannel).raw()
+}
+
+// blockedDescription reports "blocked on future <name>" for a coroutine
+// parked in Get, in place of the generic channel-blocked line, so a
+// Dispatcher.StackTrace() reader can tell which future it is waiting on.
+func (f *futureImpl) blockedDescription() string {
+	return fmt.Sprintf("blocked on future %v", f.name)
+}
+
+// Semaphore bounds the number of coroutines that may hold it at once. It is
+// the "moderator" pattern applied to a fan-out of child coroutines: acquire
+// before starting a unit of work, release when it completes, and at most n
+// units run concurrently. Create one with NewSemaphore.
+type Semaphore interface {
+	// Acquire blocks the current coroutine until a slot is available.
+	Acquire(ctx Context)
+	// TryAcquire acquires a slot without blocking, returning false if none
+	// is available.
+	TryAcquire() bool
+	// Release returns a previously acquired slot to the semaphore.
+	Release()
+}
+
+// semaphoreImpl implements Semaphore with a buffered channel of n tokens:
+// acquiring is receiving a token, releasing is sending one back. Because the
+// channel is a channelImpl it participates in deterministic Selector
+// scheduling and in Dispatcher.StackTrace() like any other blocking
+// primitive, reporting the coroutine names queued on it.
+type semaphoreImpl struct {
+	name   string
+	n      int
+	tokens Channel[struct{}]
+	// held counts slots taken via Acquire/TryAcquire, for blockedDescription.
+	// A slot taken directly by a Selector.AddAcquire case bypasses Acquire,
+	// so held can under-count while such a case is outstanding; it is only
+	// ever read for a stack trace, where that is an acceptable approximation.
+	held int
+}
+
+// NewSemaphore creates a Semaphore that admits at most n holders at a time.
+func NewSemaphore(ctx Context, n int) Semaphore {
+	ctxImpl := ctx.Value(contextKey).(*coroutineState)
+	ctxImpl.dispatcher.semaphoreSequence++
+	name := fmt.Sprintf("semaphore-%v", ctxImpl.dispatcher.semaphoreSequence)
+	tokens := NewNamedBufferedChannel[struct{}](ctx, name, n)
+	for i := 0; i < n; i++ {
+		tokens.SendAsync(struct{}{})
+	}
+	return &semaphoreImpl{name: name, n: n, tokens: tokens}
+}
+
+func (s *semaphoreImpl) Acquire(ctx Context) {
+	s.tokens.Recv(ctx)
+	s.held++
+}
+
+func (s *semaphoreImpl) TryAcquire() bool {
+	_, ok, _ := s.tokens.RecvAsync()
+	if ok {
+		s.held++
+	}
+	return ok
+}
+
+func (s *semaphoreImpl) Release() {
+	if s.tokens.SendAsync(struct{}{}) {
+		s.held--
+	}
+}
+
+func (s *semaphoreImpl) raw() *channelImpl {
+	return s.tokens.(rawChannel).raw()
+}
+
+// blockedDescription reports "blocked on semaphore <name>, <held>/<n> held"
+// for a coroutine parked in Acquire, in place of the generic
+// channel-blocked line.
+func (s *semaphoreImpl) blockedDescription() string {
+	return fmt.Sprintf("blocked on semaphore %v, %v/%v held", s.name, s.held, s.n)
+}
+
+// Clock supplies the current time to a Dispatcher's coroutines. NewTimer,
+// WithTimeout, WithDeadline, and Retry all read time through it, and sleep
+// by registering a callback with the dispatcher's timer queue rather than
+// calling time.Sleep, so tests can install a manually-advanced fake and
+// drive backoff and timeout logic deterministically, without sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// NewTimer returns a Future that becomes ready, with a nil value and error,
+// once d has elapsed on ctx's Dispatcher clock.
+func NewTimer(ctx Context, d time.Duration) Future {
+	future, settable := NewFuture(ctx)
+	ctxImpl := ctx.Value(contextKey).(*coroutineState)
+	ctxImpl.dispatcher.afterFunc(d, func() {
+		settable.Set(nil, nil)
+	})
+	return future
+}
+
+// RetryPolicy configures the backoff and retry budget used by Retry.
+type RetryPolicy struct {
+	InitialInterval    time.Duration
+	BackoffCoefficient float64
+	MaximumInterval    time.Duration
+	MaximumAttempts    int
+	ExpirationInterval time.Duration
+	// IsRetryable decides whether a failure of op should be retried. A nil
+	// IsRetryable retries every non-nil error.
+	IsRetryable func(error) bool
+}
+
+// ErrCanceled is returned by Retry when ctx is canceled before op succeeds.
+var ErrCanceled = errors.New("coroutine: context canceled")
+
+// Retry runs op, retrying with exponential backoff according to policy
+// until op succeeds, the policy is exhausted, or ctx is canceled. The
+// jitter applied to each sleep is drawn from a math/rand source seeded with
+// a coroutine-stable, monotonically increasing sequence number rather than
+// wall-clock entropy, so replaying the same history produces byte-identical
+// retry intervals.
+func Retry(ctx Context, policy RetryPolicy, op func(ctx Context) error) error {
+	ctxImpl := ctx.Value(contextKey).(*coroutineState)
+	start := dispatcherNow(ctx)
+	interval := policy.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+		if policy.IsRetryable != nil && !policy.IsRetryable(err) {
+			return err
+		}
+		if policy.MaximumAttempts > 0 && attempt >= policy.MaximumAttempts {
+			return err
+		}
+		if policy.ExpirationInterval > 0 && dispatcherNow(ctx).Sub(start) >= policy.ExpirationInterval {
+			return err
+		}
+
+		ctxImpl.dispatcher.retrySequence++
+		jittered := jitter(interval, int64(ctxImpl.dispatcher.retrySequence))
+		if policy.MaximumInterval > 0 && jittered > policy.MaximumInterval {
+			jittered = policy.MaximumInterval
+		}
+
+		timer := NewTimer(ctx, jittered)
+		fired := false
+		s := NewSelector(ctx)
+		s.AddFuture(timer, func(Future) { fired = true })
+		AddRecv(s, ctx.Done(), func(v struct{}, more bool) {})
+		s.Select(ctx)
+		if !fired {
+			return ErrCanceled
+		}
+
+		interval = time.Duration(float64(interval) * policy.BackoffCoefficient)
+		if policy.MaximumInterval > 0 && interval > policy.MaximumInterval {
+			interval = policy.MaximumInterval
+		}
+	}
+}
+
+// jitter returns interval scaled by a deterministic pseudo-random factor in
+// [0.5, 1.5), seeded from seq so the same seq always yields the same sleep.
+func jitter(interval time.Duration, seq int64) time.Duration {
+	r := rand.New(rand.NewSource(seq))
+	return time.Duration(float64(interval) * (0.5 + r.Float64()))
+}
+
+// CancelFunc cancels the Context it was returned alongside. Calling it more
+// than once, or after the Context's deadline/timeout already fired, is a
+// no-op.
+type CancelFunc func()
+
+// cancelCtx is a Context that additionally carries a Done channel, closed
+// once this Context or any ancestor created via WithCancel is canceled.
+type cancelCtx struct {
+	Context
+	done ReceiveChannel[struct{}]
+}
+
+// Done returns a channel that is closed when this Context is canceled.
+// Coroutines select on it to notice cancellation the same way they select
+// on any other channel.
+func (c *cancelCtx) Done() ReceiveChannel[struct{}] {
+	return c.done
+}
+
+// WithCancel returns a copy of parent with a new Done channel. The returned
+// CancelFunc closes that channel; cancellation also propagates down to
+// every Context derived from the result, including ones derived later via
+// WithTimeout or WithDeadline.
+//
+// Propagation is implemented with an internal coroutine, not a sync.Mutex
+// or a background `go` goroutine: the dispatcher's ExecuteUntilAllBlocked
+// loop assumes only one coroutine runs at a time, and mixing real
+// concurrency primitives into that loop is what causes shutdown races.
+// Child registration therefore happens synchronously, while the dispatcher
+// still holds that single-threaded invariant, by spawning a watcher
+// coroutine that Selects on the parent's Done channel and the child's own.
+func WithCancel(parent Context) (Context, CancelFunc) {
+	closeCh := NewNamedChannel[struct{}](parent, "cancel")
+	cc := &cancelCtx{Context: parent, done: closeCh}
+	canceled := false
+	cancel := func() {
+		if canceled {
+			return
+		}
+		canceled = true
+		closeCh.Close()
+	}
+	if parentDone, ok := parent.(interface {
+		Done() ReceiveChannel[struct{}]
+	}); ok {
+		NewCoroutine(parent, func(ctx Context) {
+			s := NewSelector(ctx)
+			AddRecv(s, parentDone.Done(), func(v struct{}, more bool) { cancel() })
+			AddRecv(s, closeCh, func(v struct{}, more bool) {})
+			s.Select(ctx)
+		})
+	}
+	return cc, cancel
+}
+
+// WithTimeout returns WithCancel(parent), arranging for the CancelFunc to
+// be invoked automatically after timeout elapses.
+func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
+	return WithDeadline(parent, dispatcherNow(parent).Add(timeout))
+}
+
+// WithDeadline returns WithCancel(parent), arranging for the CancelFunc to
+// be invoked automatically once deadline is reached. Like every other time
+// source in this package, the wait is driven by a NewTimer future rather
+// than a native timer, so it remains deterministic across replays.
+func WithDeadline(parent Context, deadline time.Time) (Context, CancelFunc) {
+	ctx, cancel := WithCancel(parent)
+	NewCoroutine(parent, func(c Context) {
+		NewTimer(c, deadline.Sub(dispatcherNow(parent))).Get(c)
+		cancel()
+	})
+	return ctx, cancel
+}
+
+// dispatcherNow returns the dispatcher's current, deterministic notion of
+// time for ctx's coroutine tree.
+func dispatcherNow(ctx Context) time.Time {
+	ctxImpl := ctx.Value(contextKey).(*coroutineState)
+	return ctxImpl.dispatcher.now()
 }
 
 // Func is a body of a coroutine
@@ -48,42 +481,89 @@ type Dispatcher interface {
 	ExecuteUntilAllBlocked() (err PanicError)
 	// IsDone returns true when all of coroutines are completed
 	IsDone() bool
-	Close()             // Destroys all coroutines without waiting for their completion
+	// Close cancels the root Context, then destroys all coroutines without
+	// waiting for their completion. Canceling first, rather than tearing
+	// coroutines down directly, lets any coroutine blocked on Recv wake up
+	// and unwind through its own defers instead of being killed mid-select.
+	Close()
 	StackTrace() string // Stack trace of all coroutines owned by the Dispatcher instance
 }
 
 // NewDispatcher creates a new Dispatcher instance with a root coroutine function.
 func NewDispatcher(root Func) Dispatcher {
 	result := &dispatcherImpl{}
-	rootCtx := new(emptyCtx)
+	rootCtx, rootCancel := WithCancel(new(emptyCtx))
+	result.rootCancel = rootCancel
 	result.newCoroutine(rootCtx, root)
 	return result
 }
 
 const contextKey = "coroutines"
 
+// typedChannel adapts the untyped channelImpl that backs every channel to
+// the generic Channel[T] surface.
+type typedChannel[T any] struct {
+	impl *channelImpl
+}
+
+func newTypedChannel[T any](impl *channelImpl) Channel[T] {
+	return &typedChannel[T]{impl: impl}
+}
+
+func (c *typedChannel[T]) Recv(ctx Context) (T, bool) {
+	v, more := c.impl.Recv(ctx)
+	return asT[T](v), more
+}
+
+func (c *typedChannel[T]) RecvAsync() (T, bool, bool) {
+	v, ok, more := c.impl.RecvAsync()
+	return asT[T](v), ok, more
+}
+
+func (c *typedChannel[T]) Send(ctx Context, v T) {
+	c.impl.Send(ctx, v)
+}
+
+func (c *typedChannel[T]) SendAsync(v T) bool {
+	return c.impl.SendAsync(v)
+}
+
+func (c *typedChannel[T]) Close() {
+	c.impl.Close()
+}
+
+func (c *typedChannel[T]) raw() *channelImpl {
+	return c.impl
+}
+
 // NewChannel create new Channel instance
-func NewChannel(ctx Context) Channel {
+func NewChannel[T any](ctx Context) Channel[T] {
 	ctxImpl := ctx.Value(contextKey).(*coroutineState)
 	ctxImpl.dispatcher.channelSequence++
-	return NewNamedChannel(ctx, fmt.Sprintf("chan-%v", ctxImpl.dispatcher.channelSequence))
+	return NewNamedChannel[T](ctx, fmt.Sprintf("chan-%v", ctxImpl.dispatcher.channelSequence))
 }
 
 // NewNamedChannel create new Channel instance with a given human readable name.
 // Name appears in stack traces that are blocked on this channel.
-func NewNamedChannel(ctx Context, name string) Channel {
-	return &channelImpl{name: name}
+func NewNamedChannel[T any](ctx Context, name string) Channel[T] {
+	return newTypedChannel[T](&channelImpl{name: name})
 }
 
 // NewBufferedChannel create new buffered Channel instance
-func NewBufferedChannel(ctx Context, size int) Channel {
-	return &channelImpl{size: size}
+func NewBufferedChannel[T any](ctx Context, size int) Channel[T] {
+	return newTypedChannel[T](&channelImpl{size: size})
 }
 
 // NewNamedBufferedChannel create new BufferedChannel instance with a given human readable name.
 // Name appears in stack traces that are blocked on this Channel.
-func NewNamedBufferedChannel(ctx Context, name string, size int) Channel {
-	return &channelImpl{name: name, size: size}
+func NewNamedBufferedChannel[T any](ctx Context, name string, size int) Channel[T] {
+	return newTypedChannel[T](&channelImpl{name: name, size: size})
+}
+
+// NewAnyChannel creates a new AnyChannel instance for call sites that need to
+// carry untyped values, e.g. a channel built up dynamically from reflection.
+func NewAnyChannel(ctx Context) AnyChannel {
+	return NewChannel[interface{}](ctx)
 }
 
 // NewSelector creates a new Selector instance.
@@ -112,4 +592,4 @@ func NewNamedCoroutine(ctx Context, name string, f Func) {
 	ctxImpl := ctx.Value(contextKey).(*coroutineState)
 	ctxImpl.dispatcher.newNamedCoroutine(ctx, name, f)
 
-}
\ No newline at end of file
+}
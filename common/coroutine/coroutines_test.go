@@ -0,0 +1,34 @@
+package coroutine
+
+import (
+	"testing"
+)
+
+// runToCompletion drives d until every coroutine is blocked or done, failing
+// the test on any panic propagated out of a coroutine.
+func runToCompletion(t *testing.T, d Dispatcher) {
+	t.Helper()
+	for !d.IsDone() {
+		if err := d.ExecuteUntilAllBlocked(); err != nil {
+			t.Fatalf("coroutine panicked: %v\n%v", err, err.StackTrace())
+		}
+	}
+}
+
+func TestChannelGenericsPreserveStaticType(t *testing.T) {
+	var got int
+	d := NewDispatcher(func(ctx Context) {
+		c := NewChannel[int](ctx)
+		NewCoroutine(ctx, func(ctx Context) {
+			c.Send(ctx, 42)
+		})
+		v, more := c.Recv(ctx)
+		got, _ = v, more
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if got != 42 {
+		t.Fatalf("got %v, want 42", got)
+	}
+}
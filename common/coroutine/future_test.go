@@ -0,0 +1,59 @@
+package coroutine
+
+import "testing"
+
+func TestFutureIsReadyWithoutPriorGet(t *testing.T) {
+	d := NewDispatcher(func(ctx Context) {
+		future, settable := NewFuture(ctx)
+		if future.IsReady() {
+			t.Error("future should not be ready before Set")
+		}
+		settable.Set("value", nil)
+		if !future.IsReady() {
+			t.Error("future should be ready immediately after Set, even without calling Get first")
+		}
+		v, err := future.Get(ctx)
+		if err != nil || v != "value" {
+			t.Errorf("Get() = (%v, %v), want (\"value\", nil)", v, err)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+}
+
+func TestFutureBlockedDescriptionNamesTheFuture(t *testing.T) {
+	d := NewDispatcher(func(ctx Context) {
+		future, _ := NewFuture(ctx)
+		describer, ok := future.(blockedDescriber)
+		if !ok {
+			t.Fatal("futureImpl should implement blockedDescriber so stack traces name the future it backs")
+		}
+		got := describer.blockedDescription()
+		want := "blocked on future future-1"
+		if got != want {
+			t.Errorf("blockedDescription() = %q, want %q", got, want)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+}
+
+func TestSettableChainForwardsOtherFuturesOutcome(t *testing.T) {
+	d := NewDispatcher(func(ctx Context) {
+		source, sourceSettable := NewFuture(ctx)
+		chained, chainedSettable := NewFuture(ctx)
+		chainedSettable.Chain(source)
+
+		if chained.IsReady() {
+			t.Error("chained future should not be ready before the source settles")
+		}
+		sourceSettable.Set("value", nil)
+
+		v, err := chained.Get(ctx)
+		if err != nil || v != "value" {
+			t.Errorf("Get() = (%v, %v), want (\"value\", nil)", v, err)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+}
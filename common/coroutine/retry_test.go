@@ -0,0 +1,74 @@
+package coroutine
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryJitterIsDeterministicAcrossReplays(t *testing.T) {
+	first := jitter(time.Second, 7)
+	second := jitter(time.Second, 7)
+	if first != second {
+		t.Fatalf("jitter(time.Second, 7) = %v then %v, want identical values for the same sequence number", first, second)
+	}
+}
+
+func TestRetrySucceedsAfterRetryableFailures(t *testing.T) {
+	attempts := 0
+	policy := RetryPolicy{
+		InitialInterval:    time.Millisecond,
+		BackoffCoefficient: 2,
+		MaximumInterval:    time.Second,
+		MaximumAttempts:    5,
+	}
+
+	var retryErr error
+	d := NewDispatcher(func(ctx Context) {
+		retryErr = Retry(ctx, policy, func(ctx Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		})
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if retryErr != nil {
+		t.Fatalf("Retry() = %v, want nil", retryErr)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %v, want 3", attempts)
+	}
+}
+
+func TestRetryStopsWhenNotRetryable(t *testing.T) {
+	wantErr := errors.New("permanent")
+	policy := RetryPolicy{
+		InitialInterval:    time.Millisecond,
+		BackoffCoefficient: 2,
+		MaximumInterval:    time.Second,
+		MaximumAttempts:    5,
+		IsRetryable:        func(err error) bool { return false },
+	}
+
+	attempts := 0
+	var retryErr error
+	d := NewDispatcher(func(ctx Context) {
+		retryErr = Retry(ctx, policy, func(ctx Context) error {
+			attempts++
+			return wantErr
+		})
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if retryErr != wantErr {
+		t.Fatalf("Retry() = %v, want %v", retryErr, wantErr)
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %v, want 1 (IsRetryable should stop further retries)", attempts)
+	}
+}
@@ -0,0 +1,56 @@
+package coroutine
+
+import "testing"
+
+// TestSelectPrefersReadyCaseOverDefault guards the ordering documented on
+// Selector.Select: every Recv/Send/Future/Acquire case is checked for
+// readiness before the default case is even considered, so a default never
+// preempts a case that is already ready.
+func TestSelectPrefersReadyCaseOverDefault(t *testing.T) {
+	var fired string
+
+	d := NewDispatcher(func(ctx Context) {
+		c := NewBufferedChannel[int](ctx, 1)
+		c.SendAsync(7)
+
+		s := NewSelector(ctx)
+		AddRecv(s, c, func(v int, more bool) { fired = "recv" })
+		s.AddDefault(func() { fired = "default" })
+
+		result := s.Select(ctx)
+		if result.Default || result.Index != 0 {
+			t.Errorf("Select() = %+v, want the recv case (Index: 0, Default: false)", result)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if fired != "recv" {
+		t.Fatalf("fired = %q, want %q: a ready case must win over a registered default", fired, "recv")
+	}
+}
+
+// TestSelectFallsBackToDefaultWhenNothingReady is the mirror case: with no
+// case ready, Select must run the default rather than block forever.
+func TestSelectFallsBackToDefaultWhenNothingReady(t *testing.T) {
+	var fired string
+
+	d := NewDispatcher(func(ctx Context) {
+		c := NewChannel[int](ctx)
+
+		s := NewSelector(ctx)
+		AddRecv(s, c, func(v int, more bool) { fired = "recv" })
+		s.AddDefault(func() { fired = "default" })
+
+		result := s.Select(ctx)
+		if !result.Default || result.Index != -1 {
+			t.Errorf("Select() = %+v, want the default case (Index: -1, Default: true)", result)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if fired != "default" {
+		t.Fatalf("fired = %q, want %q", fired, "default")
+	}
+}
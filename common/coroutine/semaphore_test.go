@@ -0,0 +1,75 @@
+package coroutine
+
+import "testing"
+
+func TestSemaphoreBoundsConcurrency(t *testing.T) {
+	const limit = 2
+	var inFlight, maxInFlight int
+
+	d := NewDispatcher(func(ctx Context) {
+		sem := NewSemaphore(ctx, limit)
+		done := NewChannel[struct{}](ctx)
+		const workers = 5
+		for i := 0; i < workers; i++ {
+			NewCoroutine(ctx, func(ctx Context) {
+				sem.Acquire(ctx)
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				inFlight--
+				sem.Release()
+				done.Send(ctx, struct{}{})
+			})
+		}
+		for i := 0; i < workers; i++ {
+			done.Recv(ctx)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if maxInFlight > limit {
+		t.Fatalf("max in-flight = %v, want <= %v", maxInFlight, limit)
+	}
+}
+
+func TestSemaphoreBlockedDescriptionReportsHeldCount(t *testing.T) {
+	d := NewDispatcher(func(ctx Context) {
+		sem := NewSemaphore(ctx, 5)
+		describer, ok := sem.(blockedDescriber)
+		if !ok {
+			t.Fatal("semaphoreImpl should implement blockedDescriber so stack traces report its held count")
+		}
+		sem.Acquire(ctx)
+		sem.Acquire(ctx)
+		sem.Acquire(ctx)
+
+		got := describer.blockedDescription()
+		want := "blocked on semaphore semaphore-1, 3/5 held"
+		if got != want {
+			t.Errorf("blockedDescription() = %q, want %q", got, want)
+		}
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+}
+
+func TestSemaphoreTryAcquireDoesNotBlock(t *testing.T) {
+	var gotSlot, gotSecondSlot bool
+
+	d := NewDispatcher(func(ctx Context) {
+		sem := NewSemaphore(ctx, 1)
+		gotSlot = sem.TryAcquire()
+		gotSecondSlot = sem.TryAcquire()
+	})
+	defer d.Close()
+	runToCompletion(t, d)
+
+	if !gotSlot {
+		t.Fatal("TryAcquire() on a fresh semaphore with capacity 1 should succeed")
+	}
+	if gotSecondSlot {
+		t.Fatal("TryAcquire() should fail once the only slot is already held")
+	}
+}